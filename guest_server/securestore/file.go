@@ -0,0 +1,178 @@
+package securestore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	fileStoreDirEnv = "ProgramData"
+	fileStoreSubdir = `WinBoat\secrets`
+)
+
+var filePolicy = policy{
+	fullMask: uint32(windows.GENERIC_ALL),
+	readMask: uint32(windows.GENERIC_READ),
+	denyMask: uint32(windows.GENERIC_WRITE | windows.DELETE | windows.WRITE_DAC | windows.WRITE_OWNER),
+}
+
+// FileStore stores each value as its own hidden file under
+// %ProgramData%\WinBoat\secrets, locked down with the same SYSTEM-full-control /
+// Everyone-read / Administrators-deny ACL RegistryStore enforces, via the same
+// applyACL/verifyACL primitive parameterized for SE_FILE_OBJECT.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a Store backed by %ProgramData%\WinBoat\secrets.
+func NewFileStore() *FileStore {
+	programData := os.Getenv(fileStoreDirEnv)
+	if programData == "" {
+		programData = `C:\ProgramData`
+	}
+	return &FileStore{dir: filepath.Join(programData, fileStoreSubdir)}
+}
+
+func (f *FileStore) probe() error {
+	return os.MkdirAll(f.dir, 0700)
+}
+
+// path resolves name to its file under f.dir. name must be a single path element - it
+// comes from the same caller-controlled Store.Get/Set/Delete names every backend
+// accepts, so it's rejected outright if it could escape f.dir (a path separator or a
+// ".." component) rather than joined blindly.
+func (f *FileStore) path(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	return filepath.Join(f.dir, name), nil
+}
+
+// Get retrieves a stored value. Returns nil if the file doesn't exist.
+func (f *FileStore) Get(name string) (*string, error) {
+	if err := f.verifyOne(name); err != nil {
+		return nil, err
+	}
+
+	path, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	value := string(data)
+	return &value, nil
+}
+
+// Set stores value as a hidden, ACL-locked file. Must be run as NT AUTHORITY\SYSTEM.
+func (f *FileStore) Set(name string, value string) error {
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+
+	path, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(value), 0600); err != nil {
+		return fmt.Errorf("failed to write secret file: %w", err)
+	}
+
+	if err := hideFile(path); err != nil {
+		return fmt.Errorf("failed to hide secret file: %w", err)
+	}
+
+	return applyACL(path, windows.SE_FILE_OBJECT, filePolicy)
+}
+
+// Delete removes the named secret file. Must be run as NT AUTHORITY\SYSTEM.
+func (f *FileStore) Delete(name string) error {
+	path, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete secret file: %w", err)
+	}
+	return nil
+}
+
+// List returns the names of every secret file currently stored.
+func (f *FileStore) List() ([]string, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list secrets directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Verify checks the ACL on every secret file, self-healing drift when running as
+// SYSTEM.
+func (f *FileStore) Verify() error {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list secrets directory: %w", err)
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		path, err := f.path(e.Name())
+		if err != nil {
+			return err
+		}
+		if err := verifyACL(path, windows.SE_FILE_OBJECT, filePolicy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *FileStore) verifyOne(name string) error {
+	path, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	return verifyACL(path, windows.SE_FILE_OBJECT, filePolicy)
+}
+
+// hideFile sets FILE_ATTRIBUTE_HIDDEN on path so the secret doesn't show up in casual
+// directory listings.
+func hideFile(path string) error {
+	pathW, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+
+	attrs, err := windows.GetFileAttributes(pathW)
+	if err != nil {
+		return err
+	}
+
+	return windows.SetFileAttributes(pathW, attrs|windows.FILE_ATTRIBUTE_HIDDEN)
+}
@@ -0,0 +1,336 @@
+package securestore
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const registryKeyPath = `SOFTWARE\WinBoatSecureStore`
+
+var registryPolicy = policy{
+	fullMask: uint32(windows.KEY_ALL_ACCESS),
+	readMask: uint32(windows.KEY_READ),
+	denyMask: uint32(windows.KEY_WRITE | windows.KEY_SET_VALUE | windows.DELETE | windows.WRITE_DAC | windows.WRITE_OWNER),
+}
+
+// RegistryStore is the original secure-store backend: values live as REG_SZ entries
+// under HKLM\SOFTWARE\WinBoatSecureStore, locked down with the SYSTEM/Everyone/
+// Admins-deny ACL applyACL/verifyACL enforce.
+type RegistryStore struct{}
+
+// NewRegistryStore returns a Store backed by HKLM\SOFTWARE\WinBoatSecureStore.
+func NewRegistryStore() *RegistryStore {
+	return &RegistryStore{}
+}
+
+// probe reports whether the registry backend can be reached at all on this host.
+func (r *RegistryStore) probe() error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, registryKeyPath, registry.QUERY_VALUE)
+	if err != nil && err != registry.ErrNotExist {
+		return err
+	}
+	if err == nil {
+		key.Close()
+	}
+	return nil
+}
+
+// Get retrieves a stored value. Returns nil if the key or value doesn't exist.
+func (r *RegistryStore) Get(name string) (*string, error) {
+	if err := r.Verify(); err != nil {
+		return nil, err
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, registryKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue(name)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read registry value: %w", err)
+	}
+
+	return &value, nil
+}
+
+// Set stores a value that only SYSTEM can modify.
+// Must be run as NT AUTHORITY\SYSTEM.
+func (r *RegistryStore) Set(name string, value string) error {
+	key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, registryKeyPath, registry.ALL_ACCESS)
+	if err != nil {
+		return fmt.Errorf("failed to create registry key: %w", err)
+	}
+	defer key.Close()
+
+	if err := key.SetStringValue(name, value); err != nil {
+		return fmt.Errorf("failed to set registry value: %w", err)
+	}
+
+	return applyACL(`MACHINE\`+registryKeyPath, windows.SE_REGISTRY_KEY, registryPolicy)
+}
+
+// Delete removes a stored value. Must be run as NT AUTHORITY\SYSTEM.
+func (r *RegistryStore) Delete(name string) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, registryKeyPath, registry.SET_VALUE)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil
+		}
+		return fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer key.Close()
+
+	if err := key.DeleteValue(name); err != nil && err != registry.ErrNotExist {
+		return fmt.Errorf("failed to delete registry value: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the names of every value currently stored.
+func (r *RegistryStore) List() ([]string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, registryKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate values: %w", err)
+	}
+
+	return names, nil
+}
+
+// Verify enumerates the current DACL on registryKeyPath and compares it against the
+// intended policy, self-healing when running as SYSTEM.
+func (r *RegistryStore) Verify() error {
+	return verifyACL(`MACHINE\`+registryKeyPath, windows.SE_REGISTRY_KEY, registryPolicy)
+}
+
+// SetEncrypted wraps value with a DPAPI-NG SYSTEM-only protection descriptor before
+// storing it, so the ACL and the encryption provide defense-in-depth: an
+// Administrator who has read access via the Everyone ACE still can't recover the
+// plaintext, since only NT AUTHORITY\SYSTEM satisfies the descriptor.
+// Must be run as NT AUTHORITY\SYSTEM.
+func (r *RegistryStore) SetEncrypted(name string, value string) error {
+	blob, err := dpapiProtect([]byte(value))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt value: %w", err)
+	}
+
+	return r.Set(name, base64.StdEncoding.EncodeToString(blob))
+}
+
+// GetEncrypted retrieves a value written by SetEncrypted and transparently unwraps it.
+func (r *RegistryStore) GetEncrypted(name string) (*string, error) {
+	encoded, err := r.Get(name)
+	if err != nil || encoded == nil {
+		return encoded, err
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(*encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode stored value: %w", err)
+	}
+
+	plaintext, err := dpapiUnprotect(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	value := string(plaintext)
+	return &value, nil
+}
+
+type valueReport struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Size    int    `json:"size"`
+	Content string `json:"content,omitempty"`
+	SHA256  string `json:"sha256,omitempty"`
+}
+
+type aceReport struct {
+	SID  string `json:"sid"`
+	Mode string `json:"mode"`
+	Mask uint32 `json:"mask"`
+}
+
+type supportReport struct {
+	Path   string        `json:"path"`
+	Values []valueReport `json:"values"`
+	ACL    []aceReport   `json:"acl"`
+}
+
+// Export enumerates every value under registryKeyPath along with its current DACL and
+// writes a JSON support-info report to w, mirroring the "log support info" pattern
+// used by other Windows daemons. When redact is true, value contents are replaced with
+// their SHA-256 hash so the report can be shared for troubleshooting without leaking
+// secrets.
+func (r *RegistryStore) Export(w io.Writer, redact bool) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, registryKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(-1)
+	if err != nil {
+		return fmt.Errorf("failed to enumerate values: %w", err)
+	}
+
+	report := supportReport{Path: `HKLM\` + registryKeyPath}
+	for _, name := range names {
+		entry, err := exportValue(key, name, redact)
+		if err != nil {
+			return fmt.Errorf("failed to read value %q: %w", name, err)
+		}
+		report.Values = append(report.Values, entry)
+	}
+
+	acl, err := r.exportACL()
+	if err != nil {
+		return fmt.Errorf("failed to read DACL: %w", err)
+	}
+	report.ACL = acl
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func exportValue(key registry.Key, name string, redact bool) (valueReport, error) {
+	_, valtype, err := key.GetValue(name, nil)
+	if err != nil {
+		return valueReport{}, err
+	}
+
+	entry := valueReport{Name: name, Type: regValueTypeName(valtype)}
+
+	var raw []byte
+	switch valtype {
+	case registry.SZ, registry.EXPAND_SZ:
+		value, _, err := key.GetStringValue(name)
+		if err != nil {
+			return valueReport{}, err
+		}
+		raw = []byte(value)
+	case registry.MULTI_SZ:
+		values, _, err := key.GetStringsValue(name)
+		if err != nil {
+			return valueReport{}, err
+		}
+		raw = []byte(fmt.Sprint(values))
+	default:
+		value, _, err := key.GetBinaryValue(name)
+		if err != nil {
+			return valueReport{}, err
+		}
+		raw = value
+	}
+	entry.Size = len(raw)
+
+	if redact {
+		sum := sha256.Sum256(raw)
+		entry.SHA256 = hex.EncodeToString(sum[:])
+	} else {
+		// Best-effort; binary values may not round-trip as valid UTF-8 but that's
+		// fine for a diagnostic report.
+		entry.Content = string(raw)
+	}
+
+	return entry, nil
+}
+
+func regValueTypeName(valtype uint32) string {
+	switch valtype {
+	case registry.SZ:
+		return "REG_SZ"
+	case registry.EXPAND_SZ:
+		return "REG_EXPAND_SZ"
+	case registry.BINARY:
+		return "REG_BINARY"
+	case registry.DWORD:
+		return "REG_DWORD"
+	case registry.DWORD_BIG_ENDIAN:
+		return "REG_DWORD_BIG_ENDIAN"
+	case registry.MULTI_SZ:
+		return "REG_MULTI_SZ"
+	case registry.QWORD:
+		return "REG_QWORD"
+	default:
+		return fmt.Sprintf("REG_UNKNOWN(%d)", valtype)
+	}
+}
+
+func (r *RegistryStore) exportACL() ([]aceReport, error) {
+	path := `MACHINE\` + registryKeyPath
+	pathW, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert path: %w", err)
+	}
+
+	var dacl *windows.ACL
+	ret, _, err := procGetNamedSecurityInfoW.Call(
+		uintptr(unsafe.Pointer(pathW)),
+		uintptr(windows.SE_REGISTRY_KEY),
+		uintptr(windows.DACL_SECURITY_INFORMATION),
+		0,
+		0,
+		uintptr(unsafe.Pointer(&dacl)),
+		0,
+		0,
+	)
+	if ret != 0 {
+		return nil, fmt.Errorf("GetNamedSecurityInfoW failed: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(dacl)))
+
+	entries, err := explicitAccessEntries(dacl)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]aceReport, 0, len(entries))
+	for _, e := range entries {
+		report = append(report, aceReport{
+			SID:  e.sid,
+			Mode: accessModeName(e.accessMode),
+			Mask: uint32(e.accessPermissions),
+		})
+	}
+
+	return report, nil
+}
+
+func accessModeName(mode windows.ACCESS_MODE) string {
+	switch mode {
+	case windows.GRANT_ACCESS:
+		return "Grant"
+	case windows.DENY_ACCESS:
+		return "Deny"
+	default:
+		return "Unknown"
+	}
+}
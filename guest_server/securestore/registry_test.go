@@ -0,0 +1,91 @@
+package securestore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+func TestRegValueTypeName(t *testing.T) {
+	cases := []struct {
+		valtype uint32
+		want    string
+	}{
+		{registry.SZ, "REG_SZ"},
+		{registry.EXPAND_SZ, "REG_EXPAND_SZ"},
+		{registry.BINARY, "REG_BINARY"},
+		{registry.DWORD, "REG_DWORD"},
+		{registry.DWORD_BIG_ENDIAN, "REG_DWORD_BIG_ENDIAN"},
+		{registry.MULTI_SZ, "REG_MULTI_SZ"},
+		{registry.QWORD, "REG_QWORD"},
+		{9999, "REG_UNKNOWN(9999)"},
+	}
+
+	for _, c := range cases {
+		if got := regValueTypeName(c.valtype); got != c.want {
+			t.Errorf("regValueTypeName(%d) = %q, want %q", c.valtype, got, c.want)
+		}
+	}
+}
+
+func TestAccessModeName(t *testing.T) {
+	cases := []struct {
+		mode windows.ACCESS_MODE
+		want string
+	}{
+		{windows.GRANT_ACCESS, "Grant"},
+		{windows.DENY_ACCESS, "Deny"},
+		{windows.REVOKE_ACCESS, "Unknown"},
+	}
+
+	for _, c := range cases {
+		if got := accessModeName(c.mode); got != c.want {
+			t.Errorf("accessModeName(%v) = %q, want %q", c.mode, got, c.want)
+		}
+	}
+}
+
+func TestSupportReportJSONShape(t *testing.T) {
+	report := supportReport{
+		Path: `HKLM\SOFTWARE\WinBoatSecureStore`,
+		Values: []valueReport{
+			{Name: "session-key", Type: "REG_SZ", Size: 4, SHA256: "deadbeef"},
+		},
+		ACL: []aceReport{
+			{SID: sidSystem, Mode: "Grant", Mask: 0x1},
+		},
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("json.Marshal(report) failed: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal(encoded) failed: %v", err)
+	}
+
+	for _, field := range []string{"path", "values", "acl"} {
+		if _, ok := decoded[field]; !ok {
+			t.Errorf("supportReport JSON is missing expected field %q: %s", field, encoded)
+		}
+	}
+
+	values, ok := decoded["values"].([]any)
+	if !ok || len(values) != 1 {
+		t.Fatalf("supportReport JSON \"values\" = %v, want a single-element array", decoded["values"])
+	}
+	value, ok := values[0].(map[string]any)
+	if !ok {
+		t.Fatalf("supportReport JSON \"values[0]\" is not an object: %v", values[0])
+	}
+	if _, present := value["content"]; present {
+		t.Errorf("valueReport JSON includes omitted \"content\" field when redacted: %s", encoded)
+	}
+	if _, present := value["sha256"]; !present {
+		t.Errorf("valueReport JSON is missing \"sha256\" field when redacted: %s", encoded)
+	}
+}
@@ -0,0 +1,170 @@
+package securestore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// defaultServiceName is the SCM service whose config blob values are used as storage
+// when ServiceConfigStore is constructed with an empty name.
+const defaultServiceName = "WinBoatService"
+
+// ServiceConfigStore packs values as a JSON object into the description field of a
+// Windows service's config (QueryServiceConfig2W/ChangeServiceConfig2W under the hood,
+// via mgr.Service.Config/UpdateConfig), for secrets that need to survive somewhere an
+// attacker who only thinks to check the registry or the filesystem won't.
+//
+// It has no ACL of its own to lock down: the SCM already restricts
+// SERVICE_CHANGE_CONFIG to Administrators/SYSTEM while granting SERVICE_QUERY_CONFIG
+// to Everyone by default. Unlike the other backends, Administrators are not denied
+// write access here.
+type ServiceConfigStore struct {
+	serviceName string
+}
+
+// NewServiceConfigStore returns a Store backed by the named service's config
+// description. defaultServiceName is used when name is empty.
+func NewServiceConfigStore(name string) *ServiceConfigStore {
+	if name == "" {
+		name = defaultServiceName
+	}
+	return &ServiceConfigStore{serviceName: name}
+}
+
+func (s *ServiceConfigStore) connect() (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to the service control manager: %w", err)
+	}
+
+	svc, err := m.OpenService(s.serviceName)
+	if err != nil {
+		m.Disconnect()
+		return nil, nil, fmt.Errorf("failed to open service %q: %w", s.serviceName, err)
+	}
+
+	return m, svc, nil
+}
+
+func (s *ServiceConfigStore) probe() error {
+	m, svc, err := s.connect()
+	if err != nil {
+		return err
+	}
+	svc.Close()
+	m.Disconnect()
+	return nil
+}
+
+func (s *ServiceConfigStore) values() (map[string]string, error) {
+	m, svc, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer m.Disconnect()
+	defer svc.Close()
+
+	cfg, err := svc.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service config: %w", err)
+	}
+
+	values := map[string]string{}
+	if cfg.Description != "" {
+		if err := json.Unmarshal([]byte(cfg.Description), &values); err != nil {
+			return nil, fmt.Errorf("failed to parse stored service config values: %w", err)
+		}
+	}
+
+	return values, nil
+}
+
+func (s *ServiceConfigStore) saveValues(values map[string]string) error {
+	m, svc, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer svc.Close()
+
+	cfg, err := svc.Config()
+	if err != nil {
+		return fmt.Errorf("failed to query service config: %w", err)
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode service config values: %w", err)
+	}
+	cfg.Description = string(encoded)
+
+	if err := svc.UpdateConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update service config: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a stored value. Returns nil if it doesn't exist.
+func (s *ServiceConfigStore) Get(name string) (*string, error) {
+	values, err := s.values()
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := values[name]
+	if !ok {
+		return nil, nil
+	}
+	return &value, nil
+}
+
+// Set stores value under name. Must be run as an account holding
+// SERVICE_CHANGE_CONFIG on the target service (Administrators or SYSTEM).
+func (s *ServiceConfigStore) Set(name string, value string) error {
+	values, err := s.values()
+	if err != nil {
+		return err
+	}
+
+	values[name] = value
+	return s.saveValues(values)
+}
+
+// Delete removes the named value. Must be run as an account holding
+// SERVICE_CHANGE_CONFIG on the target service.
+func (s *ServiceConfigStore) Delete(name string) error {
+	values, err := s.values()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := values[name]; !ok {
+		return nil
+	}
+	delete(values, name)
+
+	return s.saveValues(values)
+}
+
+// List returns the names of every value currently stored.
+func (s *ServiceConfigStore) List() ([]string, error) {
+	values, err := s.values()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Verify is a no-op: the SCM enforces the SERVICE_CHANGE_CONFIG/SERVICE_QUERY_CONFIG
+// split itself, so there's no separate DACL for this backend to drift.
+func (s *ServiceConfigStore) Verify() error {
+	return nil
+}
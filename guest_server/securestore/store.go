@@ -0,0 +1,42 @@
+// Package securestore gives the rest of winboat-server a single place to stash secrets
+// that must survive tampering by a non-SYSTEM administrator: HTTP session keys, RDP
+// credentials, update tokens. RegistryStore and FileStore enforce the same
+// SYSTEM-full-control / Everyone-read / Administrators-deny policy using the ACL-locking
+// helpers in acl_windows.go. ServiceConfigStore applies no ACL of its own and relies on
+// the SCM's default service permissions, which let Administrators change the stored
+// values too.
+package securestore
+
+import "fmt"
+
+// Store is a pluggable secure-storage backend.
+type Store interface {
+	// Get returns the named value, or nil if it doesn't exist.
+	Get(name string) (*string, error)
+	// Set stores value under name. Must be run as NT AUTHORITY\SYSTEM.
+	Set(name string, value string) error
+	// Delete removes the named value. Must be run as NT AUTHORITY\SYSTEM.
+	Delete(name string) error
+	// List returns the names of every value currently stored.
+	List() ([]string, error)
+	// Verify checks the backend's ACL against its intended policy, self-healing the
+	// drift when running as SYSTEM, and returns a descriptive error if it could not.
+	Verify() error
+}
+
+// Default returns the best Store available on this host: RegistryStore when
+// HKLM\SOFTWARE\WinBoatSecureStore can be reached, falling back to FileStore under
+// %ProgramData%\WinBoat\secrets otherwise.
+func Default() (Store, error) {
+	rs := NewRegistryStore()
+	if err := rs.probe(); err == nil {
+		return rs, nil
+	}
+
+	fs := NewFileStore()
+	if err := fs.probe(); err != nil {
+		return nil, fmt.Errorf("no secure store backend is available on this host: %w", err)
+	}
+
+	return fs, nil
+}